@@ -0,0 +1,120 @@
+package datamodels
+
+import (
+	"fmt"
+	"github.com/adamhei/historicalapi/errors"
+	"sort"
+)
+
+// AggregateStats bundles the per-bucket summary statistics a downsampled window of Candles can report.
+// Which fields are populated depends on which ?stats= were requested
+type AggregateStats struct {
+	Timestamp int64   `json:"timestamp"`
+	Open      float64 `json:"open,omitempty"`
+	High      float64 `json:"high,omitempty"`
+	Low       float64 `json:"low,omitempty"`
+	Close     float64 `json:"close,omitempty"`
+	Min       float64 `json:"min,omitempty"`
+	Max       float64 `json:"max,omitempty"`
+	Median    float64 `json:"median,omitempty"`
+	Mean      float64 `json:"mean,omitempty"`
+}
+
+// Supported ?aggregate= coarser bucket widths, in seconds
+var aggregateWindowToGranularity = map[string]int64{
+	"day":   dailyBySeconds,
+	"week":  dailyBySeconds * 7,
+	"month": dailyBySeconds * 30,
+}
+
+// Aggregate downsamples candles into coarser, aggregateWindow-wide buckets, computing whichever stats
+// are requested ("ohlc", "band", or both). candles need not be sorted or contiguous: a trailing bucket
+// with fewer candles than a full window is aggregated from whatever it has, and a bucket with no
+// candles at all (a gap) is omitted rather than synthesized
+func Aggregate(candles []Candle, aggregateWindow string, stats []string) ([]AggregateStats, *errors.MyError) {
+	granularity, ok := aggregateWindowToGranularity[aggregateWindow]
+	if !ok {
+		return nil, &errors.MyError{Err: fmt.Sprintf("Please provide a valid aggregate window; %s is invalid", aggregateWindow), ErrorCode: 400}
+	}
+
+	buckets := make(map[int64][]Candle)
+	bucketOrder := make([]int64, 0)
+	for _, candle := range candles {
+		bucketTimestamp := (candle.Timestamp / granularity) * granularity
+		if _, seen := buckets[bucketTimestamp]; !seen {
+			bucketOrder = append(bucketOrder, bucketTimestamp)
+		}
+		buckets[bucketTimestamp] = append(buckets[bucketTimestamp], candle)
+	}
+
+	sort.Slice(bucketOrder, func(i, j int) bool { return bucketOrder[i] < bucketOrder[j] })
+
+	wantOHLC := containsString(stats, "ohlc")
+	wantBand := containsString(stats, "band")
+
+	aggregated := make([]AggregateStats, 0, len(bucketOrder))
+	for _, bucketTimestamp := range bucketOrder {
+		bucketCandles := buckets[bucketTimestamp]
+		stat := AggregateStats{Timestamp: bucketTimestamp}
+
+		if wantOHLC {
+			// Open/Close depend on chronological order within the bucket, which candles doesn't
+			// guarantee - GDAX's historic-rates response (and getIntervalPartition's own partitioning)
+			// comes back newest-first
+			sort.Slice(bucketCandles, func(i, j int) bool { return bucketCandles[i].Timestamp < bucketCandles[j].Timestamp })
+
+			stat.Open = bucketCandles[0].Open
+			stat.Close = bucketCandles[len(bucketCandles)-1].Close
+			stat.High, stat.Low = highLow(bucketCandles)
+		}
+
+		if wantBand {
+			stat.Min, stat.Max, stat.Median, stat.Mean = band(bucketCandles)
+		}
+
+		aggregated = append(aggregated, stat)
+	}
+
+	return aggregated, nil
+}
+
+// highLow returns the highest high and lowest low across candles
+func highLow(candles []Candle) (high float64, low float64) {
+	high, low = candles[0].High, candles[0].Low
+
+	for _, candle := range candles[1:] {
+		if candle.High > high {
+			high = candle.High
+		}
+		if candle.Low < low {
+			low = candle.Low
+		}
+	}
+
+	return high, low
+}
+
+// band computes the min, max, median, and mean close price across candles
+func band(candles []Candle) (min float64, max float64, median float64, mean float64) {
+	closes := make([]float64, len(candles))
+	sum := 0.0
+	for index, candle := range candles {
+		closes[index] = candle.Close
+		sum += candle.Close
+	}
+
+	sort.Float64s(closes)
+
+	min = closes[0]
+	max = closes[len(closes)-1]
+	mean = sum / float64(len(closes))
+
+	mid := len(closes) / 2
+	if len(closes)%2 == 0 {
+		median = (closes[mid-1] + closes[mid]) / 2
+	} else {
+		median = closes[mid]
+	}
+
+	return min, max, median, mean
+}
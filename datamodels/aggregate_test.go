@@ -0,0 +1,78 @@
+package datamodels
+
+import "testing"
+
+func TestAggregatePartialTrailingBucket(t *testing.T) {
+	candles := []Candle{
+		{Timestamp: 0, Open: 100, High: 110, Low: 90, Close: 105, Volume: 1},
+		{Timestamp: dailyBySeconds, Open: 105, High: 120, Low: 100, Close: 115, Volume: 1},
+		// Only one candle in the second weekly bucket, simulating a window cut off mid-week
+		{Timestamp: dailyBySeconds * 7, Open: 200, High: 210, Low: 190, Close: 205, Volume: 1},
+	}
+
+	aggregated, myerror := Aggregate(candles, "week", []string{"ohlc", "band"})
+	if myerror != nil {
+		t.Fatalf("unexpected error: %v", myerror.Err)
+	}
+	if len(aggregated) != 2 {
+		t.Fatalf("expected 2 buckets, got %d", len(aggregated))
+	}
+
+	full := aggregated[0]
+	if full.Open != 100 || full.Close != 115 || full.High != 120 || full.Low != 90 {
+		t.Errorf("unexpected OHLC for full bucket: %+v", full)
+	}
+
+	trailing := aggregated[1]
+	if trailing.Open != 200 || trailing.Close != 205 {
+		t.Errorf("expected the partial trailing bucket to aggregate from its single candle, got %+v", trailing)
+	}
+}
+
+func TestAggregateSkipsEmptyGaps(t *testing.T) {
+	candles := []Candle{
+		{Timestamp: 0, Open: 100, High: 100, Low: 100, Close: 100, Volume: 1},
+		// A full week of missing candles before the next one
+		{Timestamp: dailyBySeconds * 14, Open: 200, High: 200, Low: 200, Close: 200, Volume: 1},
+	}
+
+	aggregated, myerror := Aggregate(candles, "week", []string{"ohlc"})
+	if myerror != nil {
+		t.Fatalf("unexpected error: %v", myerror.Err)
+	}
+
+	if len(aggregated) != 2 {
+		t.Fatalf("expected the empty week to be omitted rather than synthesized, got %d buckets", len(aggregated))
+	}
+	if aggregated[0].Timestamp == aggregated[1].Timestamp {
+		t.Errorf("expected two distinct buckets, got %+v", aggregated)
+	}
+}
+
+func TestAggregateRejectsUnknownWindow(t *testing.T) {
+	_, myerror := Aggregate([]Candle{{Timestamp: 0}}, "fortnight", []string{"ohlc"})
+	if myerror == nil {
+		t.Fatal("expected an error for an unsupported aggregate window")
+	}
+}
+
+func TestAggregateHandlesDescendingInput(t *testing.T) {
+	// GDAX's historic-rates response (and getIntervalPartition's own partitioning) comes back newest-first
+	candles := []Candle{
+		{Timestamp: dailyBySeconds, Open: 105, High: 120, Low: 100, Close: 115, Volume: 1},
+		{Timestamp: 0, Open: 100, High: 110, Low: 90, Close: 105, Volume: 1},
+	}
+
+	aggregated, myerror := Aggregate(candles, "week", []string{"ohlc"})
+	if myerror != nil {
+		t.Fatalf("unexpected error: %v", myerror.Err)
+	}
+	if len(aggregated) != 1 {
+		t.Fatalf("expected both candles to fall in the same weekly bucket, got %d", len(aggregated))
+	}
+
+	bucket := aggregated[0]
+	if bucket.Open != 100 || bucket.Close != 115 {
+		t.Errorf("expected Open/Close to reflect chronological order despite descending input, got %+v", bucket)
+	}
+}
@@ -0,0 +1,257 @@
+package datamodels
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/adamhei/historicalapi/errors"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Supported intervals and their granularities. Binance's klines endpoint takes a granularity string
+// rather than a number of seconds, so these values can't be shared with gdaxIntervalToGranularity
+var binanceIntervalToGranularity = map[string]string{
+	TWOYEAR:    "1d",
+	YEAR:       "1d",
+	SIXMONTH:   "1d",
+	THREEMONTH: "1d",
+	MONTH:      "4h",
+	WEEK:       "1h",
+	DAY:        "15m",
+}
+
+const binanceHistoricalEndpoint = "https://api.binance.com/api/v3/klines"
+
+// binanceGranularitySecondsToToken maps the candle-width seconds used by the raw start/end/granularity
+// mode to Binance's interval tokens, since Binance itself doesn't speak in seconds
+var binanceGranularitySecondsToToken = map[int64]string{
+	minuteBySeconds:        "1m",
+	fiveminuteBySeconds:    "5m",
+	fifteenminuteBySeconds: "15m",
+	hourBySeconds:          "1h",
+	fourhourBySeconds:      "4h",
+	dailyBySeconds:         "1d",
+}
+
+// BinanceExchange is the HistoricalExchange implementation backed by Binance's /api/v3/klines endpoint
+type BinanceExchange struct{}
+
+func (BinanceExchange) Name() string {
+	return "binance"
+}
+
+// SupportedGranularities returns the candle-width seconds Binance accepts, as strings, matching the
+// seconds-based contract ValidateRange and the other HistoricalExchange implementations use - not
+// Binance's own interval tokens ("1d", "4h", ...), which ValidateRange never sees
+func (BinanceExchange) SupportedGranularities() []string {
+	granularities := make([]string, 0, len(binanceGranularitySecondsToToken))
+	for seconds := range binanceGranularitySecondsToToken {
+		granularities = append(granularities, strconv.FormatInt(seconds, 10))
+	}
+
+	return granularities
+}
+
+// binanceGranularitySeconds converts a Binance interval token back to candle-width seconds, so the warm
+// cache (which always keys on seconds) can be shared between the named-interval and raw-range paths
+func binanceGranularitySeconds(token string) int64 {
+	for seconds, candidate := range binanceGranularitySecondsToToken {
+		if candidate == token {
+			return seconds
+		}
+	}
+
+	return 0
+}
+
+// FetchCandles checks interval's validity and attempts to return all Binance candles for pair within that
+// interval, at a pre-determined granularity
+func (b BinanceExchange) FetchCandles(pair string, interval string) ([]PricePoint, *errors.MyError) {
+	interval = strings.ToUpper(interval)
+	granularity, ok := binanceIntervalToGranularity[interval]
+	if !ok {
+		return nil, &errors.MyError{Err: fmt.Sprintf("Please provide a valid interval; %s is invalid", interval), ErrorCode: 400}
+	}
+
+	granularitySeconds := binanceGranularitySeconds(granularity)
+	partition := getIntervalPartition(interval)
+
+	return withCache(b.Name(), pair, interval, granularitySeconds, partition, func() ([]PricePoint, *errors.MyError) {
+		buckets, myerror := fetchBinanceBuckets(pair, granularity, partition)
+		if myerror != nil {
+			return nil, myerror
+		}
+
+		log.Println(fmt.Sprintf("Found %d buckets from Binance", len(buckets)))
+
+		return generalizeBinanceBuckets(buckets), nil
+	})
+}
+
+// FetchRange validates a caller-supplied start/end/granularity range and attempts to return all Binance
+// candles for pair within it, auto-partitioning the range into MaxTotalCandles-candle requests
+func (b BinanceExchange) FetchRange(pair string, start time.Time, end time.Time, granularity int64) ([]PricePoint, *errors.MyError) {
+	if myerror := ValidateRange(start, end, granularity, b.SupportedGranularities()); myerror != nil {
+		return nil, myerror
+	}
+
+	partition := getCustomPartition(start, end, granularity)
+
+	return withCache(b.Name(), pair, rangeCacheInterval, granularity, partition, func() ([]PricePoint, *errors.MyError) {
+		buckets, myerror := fetchBinanceBuckets(pair, binanceGranularitySecondsToToken[granularity], partition)
+		if myerror != nil {
+			return nil, myerror
+		}
+
+		log.Println(fmt.Sprintf("Found %d buckets from Binance", len(buckets)))
+
+		return generalizeBinanceBuckets(buckets), nil
+	})
+}
+
+// StreamCandles writes each Binance kline to writer as soon as the chunk of requests covering it
+// returns, instead of accumulating the whole interval in memory first - worthwhile for wide intervals
+// like TWOYEAR where buffering every kline would otherwise sit in memory for the whole request. It skips
+// the warm cache, since a bulk export like this is rarely requested twice
+func (b BinanceExchange) StreamCandles(pair string, interval string, writer CandleWriter) *errors.MyError {
+	interval = strings.ToUpper(interval)
+	granularity, ok := binanceIntervalToGranularity[interval]
+	if !ok {
+		return &errors.MyError{Err: fmt.Sprintf("Please provide a valid interval; %s is invalid", interval), ErrorCode: 400}
+	}
+
+	written := make(map[int64]bool)
+	for _, period := range getIntervalPartition(interval) {
+		buckets, myerror := fetchBinanceBuckets(pair, granularity, []timePeriod{period})
+		if myerror != nil {
+			return myerror
+		}
+
+		for _, point := range generalizeBinanceBuckets(buckets) {
+			if written[point.Timestamp] {
+				continue
+			}
+			written[point.Timestamp] = true
+
+			if err := writer.WritePoint(point); err != nil {
+				return &errors.MyError{Err: err.Error(), ErrorCode: http.StatusInternalServerError}
+			}
+		}
+	}
+
+	return nil
+}
+
+// StreamRange is StreamCandles's raw start/end/granularity counterpart: it validates the range, then
+// writes each Binance kline to writer as soon as the chunk covering it returns, deduping across chunks
+// the same way StreamCandles dedupes across named-interval partitions
+func (b BinanceExchange) StreamRange(pair string, start time.Time, end time.Time, granularity int64, writer CandleWriter) *errors.MyError {
+	if myerror := ValidateRange(start, end, granularity, b.SupportedGranularities()); myerror != nil {
+		return myerror
+	}
+
+	token := binanceGranularitySecondsToToken[granularity]
+
+	written := make(map[int64]bool)
+	for _, period := range getCustomPartition(start, end, granularity) {
+		buckets, myerror := fetchBinanceBuckets(pair, token, []timePeriod{period})
+		if myerror != nil {
+			return myerror
+		}
+
+		for _, point := range generalizeBinanceBuckets(buckets) {
+			if written[point.Timestamp] {
+				continue
+			}
+			written[point.Timestamp] = true
+
+			if err := writer.WritePoint(point); err != nil {
+				return &errors.MyError{Err: err.Error(), ErrorCode: http.StatusInternalServerError}
+			}
+		}
+	}
+
+	return nil
+}
+
+// Given a trading pair, its Binance granularity, and a partition of the range to cover, return the raw
+// klines across that partition
+func fetchBinanceBuckets(pair string, granularity string, intervalPartition []timePeriod) ([][]interface{}, *errors.MyError) {
+	buckets := make([][]interface{}, 0)
+	for _, timePeriod := range intervalPartition {
+		requestString, err := buildBinanceRequest(pair, granularity, timePeriod.start, timePeriod.end)
+
+		if err != nil {
+			return nil, &errors.MyError{Err: err.Error()}
+		}
+
+		response, err := http.Get(requestString)
+		log.Println(fmt.Sprintf("Querying %s", requestString))
+
+		if err != nil {
+			log.Println("Could not reach ", requestString)
+			return nil, &errors.MyError{Err: "Failed to reach Binance API", ErrorCode: http.StatusInternalServerError}
+		}
+
+		if response.StatusCode == http.StatusOK {
+			tempBuckets := make([][]interface{}, 0)
+			err = json.NewDecoder(response.Body).Decode(&tempBuckets)
+			response.Body.Close()
+
+			if err != nil {
+				log.Println("Could not decode Binance response")
+				return nil, &errors.MyError{Err: err.Error(), ErrorCode: http.StatusInternalServerError}
+			}
+
+			buckets = append(buckets, tempBuckets...)
+		} else {
+			response.Body.Close()
+			return nil, &errors.MyError{Err: "Binance API returned a non-200 response", ErrorCode: response.StatusCode}
+		}
+	}
+
+	return buckets, nil
+}
+
+// Convert raw Binance klines (open time, open, high, low, close, volume, ...) to the more general PricePoints
+func generalizeBinanceBuckets(buckets [][]interface{}) []PricePoint {
+	pricePoints := make([]PricePoint, len(buckets))
+
+	for index, val := range buckets {
+		openTimeMillis := val[0].(float64)
+		price := val[4].(string)
+		pricePoints[index] = PricePoint{Timestamp: int64(openTimeMillis) / 1000, Price: price}
+	}
+
+	return pricePoints
+}
+
+// binanceSymbol converts our hyphenated pair convention (e.g. BTC-USD) into Binance's concatenated
+// symbol convention (e.g. BTCUSD)
+func binanceSymbol(pair string) string {
+	return strings.ToUpper(strings.Replace(pair, "-", "", -1))
+}
+
+// Given a trading pair, a Binance granularity, and start and end times, buildBinanceRequest returns the
+// formatted GET request URL for the Binance klines API
+// Ex: https://api.binance.com/api/v3/klines?symbol=BTCUSD&interval=1h&startTime=...&endTime=...
+func buildBinanceRequest(pair string, granularity string, start time.Time, end time.Time) (string, error) {
+	req, err := http.NewRequest("GET", binanceHistoricalEndpoint, nil)
+	if err != nil {
+		log.Println("Could not build Binance historical URL")
+		return "", err
+	}
+
+	q := req.URL.Query()
+
+	q.Add("symbol", binanceSymbol(pair))
+	q.Add("interval", granularity)
+	q.Add("startTime", strconv.FormatInt(start.Unix()*1000, 10))
+	q.Add("endTime", strconv.FormatInt(end.Unix()*1000, 10))
+
+	req.URL.RawQuery = q.Encode()
+	return req.URL.String(), nil
+}
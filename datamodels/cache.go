@@ -0,0 +1,240 @@
+package datamodels
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/adamhei/historicalapi/errors"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// cacheEntry is what we persist per bucket. Gap marks an expected timestamp the fetch returned no point
+// for (for GDAX, one FillGaps could not interpolate either), so repeat requests over the same outage
+// still count as a cache hit instead of re-fetching forever
+type cacheEntry struct {
+	Point PricePoint `json:"point"`
+	Gap   bool       `json:"gap,omitempty"`
+}
+
+// bucketCache memoizes individual candle buckets across repeat requests for the same window, keyed by
+// (exchange, product, interval, granularity, timestamp, day-bucket). Once an exchange reports a bucket it never
+// changes, except for the trailing (today's) bucket, which is still accumulating trades
+type bucketCache struct {
+	mu     sync.RWMutex
+	points map[string]cacheEntry
+	hits   int64
+	misses int64
+}
+
+// historicalCache is the process-wide warm cache every HistoricalExchange fetches through
+var historicalCache = newBucketCache()
+
+// rangeCacheInterval is the cache key component FetchRange uses in place of a named interval, so that
+// raw start/end/granularity requests over overlapping windows share the same cached buckets
+const rangeCacheInterval = "RANGE"
+
+func newBucketCache() *bucketCache {
+	return &bucketCache{points: make(map[string]cacheEntry)}
+}
+
+// cacheKey builds the memoization key for a single candle bucket. The timestamp itself keeps sub-daily
+// granularities (hourly, 15m, ...) from colliding within the same day; the trailing day-bucket is kept
+// alongside it purely so evictTrailingBucket can still find and drop today's entries by key suffix
+func cacheKey(exchange string, product string, interval string, granularity int64, timestamp int64) string {
+	dayBucket := timestamp / dailyBySeconds
+	return fmt.Sprintf("%s|%s|%s|%d|%d|%d", exchange, product, interval, granularity, timestamp, dayBucket)
+}
+
+// getAll returns the cached PricePoint for every timestamp, or false if any single one is missing -
+// a partial hit is treated as a miss since the caller would otherwise have to patch the gap itself.
+// A timestamp cached as a confirmed Gap still counts toward the hit; it just contributes no point,
+// the same way FillGaps itself would leave it out
+func (c *bucketCache) getAll(exchange string, product string, interval string, granularity int64, timestamps []int64) ([]PricePoint, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	points := make([]PricePoint, 0, len(timestamps))
+	for _, timestamp := range timestamps {
+		entry, ok := c.points[cacheKey(exchange, product, interval, granularity, timestamp)]
+		if !ok {
+			c.misses++
+			return nil, false
+		}
+		c.hits++
+		if entry.Gap {
+			continue
+		}
+		points = append(points, entry.Point)
+	}
+
+	return points, true
+}
+
+// setAll warms the cache with a fresh fetch's results, keyed per bucket by its own timestamp. Any
+// timestamp in expected that fetch didn't return is cached as a confirmed Gap, so a genuine outage
+// doesn't force every future request over the same window back to the exchange
+func (c *bucketCache) setAll(exchange string, product string, interval string, granularity int64, expected []int64, points []PricePoint) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	seen := make(map[int64]bool, len(points))
+	for _, point := range points {
+		seen[point.Timestamp] = true
+		c.points[cacheKey(exchange, product, interval, granularity, point.Timestamp)] = cacheEntry{Point: point}
+	}
+
+	for _, timestamp := range expected {
+		if !seen[timestamp] {
+			c.points[cacheKey(exchange, product, interval, granularity, timestamp)] = cacheEntry{Gap: true}
+		}
+	}
+}
+
+// Stats returns the running hit/miss counters
+func (c *bucketCache) Stats() (hits int64, misses int64) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return c.hits, c.misses
+}
+
+// Invalidate clears every cached bucket and resets the hit/miss counters. Exported so tests can start
+// from a clean cache
+func (c *bucketCache) Invalidate() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.points = make(map[string]cacheEntry)
+	c.hits = 0
+	c.misses = 0
+}
+
+// trailingDayBucket is the day-bucket index of "today", the only bucket still mutable enough to need refreshing
+func trailingDayBucket() int64 {
+	return time.Now().Unix() / dailyBySeconds
+}
+
+// evictTrailingBucket drops every cached entry whose day-bucket is today, forcing the next request for
+// it to re-fetch from the exchange. Older, immutable buckets are left untouched
+func (c *bucketCache) evictTrailingBucket() {
+	suffix := fmt.Sprintf("|%d", trailingDayBucket())
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for key := range c.points {
+		if strings.HasSuffix(key, suffix) {
+			delete(c.points, key)
+		}
+	}
+}
+
+// StartRefresh launches a goroutine that evicts the trailing bucket every refreshInterval, until stop
+// is closed
+func (c *bucketCache) StartRefresh(refreshInterval time.Duration, stop <-chan struct{}) {
+	go func() {
+		ticker := time.NewTicker(refreshInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				c.evictTrailingBucket()
+			case <-stop:
+				return
+			}
+		}
+	}()
+}
+
+// SaveToDisk persists the cache to path as JSON so a restart doesn't need to re-fetch everything
+func (c *bucketCache) SaveToDisk(path string) error {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	return json.NewEncoder(file).Encode(c.points)
+}
+
+// LoadFromDisk reloads a cache previously written by SaveToDisk. A missing file just leaves the cache cold
+func (c *bucketCache) LoadFromDisk(path string) error {
+	file, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return json.NewDecoder(file).Decode(&c.points)
+}
+
+// StartCacheRefresh begins refreshing the process-wide warm cache's trailing bucket every refreshInterval.
+// Meant to be called once at startup, alongside LoadCache
+func StartCacheRefresh(refreshInterval time.Duration, stop <-chan struct{}) {
+	historicalCache.StartRefresh(refreshInterval, stop)
+}
+
+// LoadCache reloads the process-wide warm cache from path. Meant to be called once at startup, before
+// serving any requests
+func LoadCache(path string) error {
+	return historicalCache.LoadFromDisk(path)
+}
+
+// SaveCache persists the process-wide warm cache to path. Meant to be called on shutdown
+func SaveCache(path string) error {
+	return historicalCache.SaveToDisk(path)
+}
+
+// InvalidateCache clears the process-wide warm cache. Exposed for tests
+func InvalidateCache() {
+	historicalCache.Invalidate()
+}
+
+// CacheStats returns the process-wide warm cache's hit/miss counters. Exposed for tests
+func CacheStats() (hits int64, misses int64) {
+	return historicalCache.Stats()
+}
+
+// expectedTimestamps enumerates every bucket timestamp a partition should produce at granularity, so the
+// cache can be checked for a complete hit before making any network request
+func expectedTimestamps(partition []timePeriod, granularity int64) []int64 {
+	timestamps := make([]int64, 0)
+
+	for _, period := range partition {
+		for t := period.start.Unix(); t <= period.end.Unix(); t += granularity {
+			timestamps = append(timestamps, t)
+		}
+	}
+
+	return timestamps
+}
+
+// withCache serves partition's candles from the warm cache when every expected bucket is already
+// present, falling back to fetch (and warming the cache with its result) otherwise
+func withCache(exchangeName string, product string, interval string, granularity int64, partition []timePeriod, fetch func() ([]PricePoint, *errors.MyError)) ([]PricePoint, *errors.MyError) {
+	expected := expectedTimestamps(partition, granularity)
+
+	if points, ok := historicalCache.getAll(exchangeName, product, interval, granularity, expected); ok {
+		return points, nil
+	}
+
+	points, myerror := fetch()
+	if myerror != nil {
+		return nil, myerror
+	}
+
+	historicalCache.setAll(exchangeName, product, interval, granularity, expected, points)
+	return points, nil
+}
@@ -0,0 +1,69 @@
+package datamodels
+
+import "testing"
+
+func TestBucketCacheMissThenHit(t *testing.T) {
+	c := newBucketCache()
+	timestamps := []int64{0, hourBySeconds}
+
+	if _, ok := c.getAll("gdax", "BTC-USD", "DAY", hourBySeconds, timestamps); ok {
+		t.Fatal("expected a cold cache to miss")
+	}
+
+	points := []PricePoint{
+		{Timestamp: 0, Price: "100"},
+		{Timestamp: hourBySeconds, Price: "110"},
+	}
+	c.setAll("gdax", "BTC-USD", "DAY", hourBySeconds, timestamps, points)
+
+	got, ok := c.getAll("gdax", "BTC-USD", "DAY", hourBySeconds, timestamps)
+	if !ok {
+		t.Fatal("expected a full hit after warming the cache")
+	}
+	if len(got) != 2 || got[0].Price != "100" || got[1].Price != "110" {
+		t.Errorf("expected the cached points back unchanged, got %+v", got)
+	}
+}
+
+func TestBucketCacheDistinguishesSameDaySubDailyTimestamps(t *testing.T) {
+	c := newBucketCache()
+	timestamps := []int64{0, hourBySeconds, hourBySeconds * 2}
+	points := []PricePoint{
+		{Timestamp: 0, Price: "100"},
+		{Timestamp: hourBySeconds, Price: "110"},
+		{Timestamp: hourBySeconds * 2, Price: "120"},
+	}
+
+	c.setAll("gdax", "BTC-USD", "DAY", hourBySeconds, timestamps, points)
+
+	got, ok := c.getAll("gdax", "BTC-USD", "DAY", hourBySeconds, timestamps)
+	if !ok {
+		t.Fatal("expected a full hit")
+	}
+	if len(got) != 3 {
+		t.Fatalf("expected all 3 same-day hourly candles to be cached distinctly, got %d", len(got))
+	}
+	if got[0].Price != "100" || got[1].Price != "110" || got[2].Price != "120" {
+		t.Errorf("expected each hourly candle to keep its own price, got %+v", got)
+	}
+}
+
+func TestBucketCacheRecordsGapsAsHits(t *testing.T) {
+	c := newBucketCache()
+	timestamps := []int64{0, hourBySeconds, hourBySeconds * 2}
+	// The candle at hourBySeconds never came back from the exchange - a confirmed outage, not a miss
+	points := []PricePoint{
+		{Timestamp: 0, Price: "100"},
+		{Timestamp: hourBySeconds * 2, Price: "120"},
+	}
+
+	c.setAll("gdax", "BTC-USD", "DAY", hourBySeconds, timestamps, points)
+
+	got, ok := c.getAll("gdax", "BTC-USD", "DAY", hourBySeconds, timestamps)
+	if !ok {
+		t.Fatal("expected the confirmed gap to still count as a full hit")
+	}
+	if len(got) != 2 {
+		t.Fatalf("expected the gap to contribute no point, got %d points back", len(got))
+	}
+}
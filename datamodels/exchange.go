@@ -0,0 +1,91 @@
+package datamodels
+
+import (
+	"github.com/adamhei/historicalapi/errors"
+	"strings"
+	"time"
+)
+
+// HistoricalExchange is implemented by each historical data backend we poll (GDAX, Binance, FTX, ...).
+// PollHistorical picks an implementation by name and delegates to FetchCandles so callers don't need
+// to know how any individual exchange represents pairs, granularities, or candle payloads.
+type HistoricalExchange interface {
+	// Name is the identifier a caller passes as ?exchange=
+	Name() string
+
+	// SupportedGranularities returns the candle-width seconds, as strings, that FetchRange and StreamRange
+	// accept for this exchange. The order of the returned slice is not defined
+	SupportedGranularities() []string
+
+	// FetchCandles returns pair's candles for interval, translated into PricePoints
+	FetchCandles(pair string, interval string) ([]PricePoint, *errors.MyError)
+
+	// FetchRange returns pair's candles between start and end at granularity, bypassing the named
+	// interval enum entirely. Implementations auto-partition the range to stay within their own
+	// per-request candle limit
+	FetchRange(pair string, start time.Time, end time.Time, granularity int64) ([]PricePoint, *errors.MyError)
+
+	// StreamCandles is FetchCandles's streaming counterpart: it writes each bucket to writer as soon as
+	// its underlying request returns, rather than buffering the full result in memory
+	StreamCandles(pair string, interval string, writer CandleWriter) *errors.MyError
+
+	// StreamRange is FetchRange's streaming counterpart: it writes each bucket of the raw start/end/
+	// granularity range to writer as soon as the chunk covering it returns
+	StreamRange(pair string, start time.Time, end time.Time, granularity int64, writer CandleWriter) *errors.MyError
+}
+
+// defaultExchangeName is used whenever ?exchange= is omitted or doesn't match a registered exchange
+const defaultExchangeName = "gdax"
+
+// exchanges is the registry of every HistoricalExchange we know how to poll, keyed by Name()
+var exchanges = map[string]HistoricalExchange{
+	"gdax":    GdaxExchange{},
+	"binance": BinanceExchange{},
+	"ftx":     FtxExchange{},
+}
+
+// PollHistorical resolves name to a registered HistoricalExchange, falling back to GDAX, and fetches
+// pair's candles for interval from it
+func PollHistorical(name string, pair string, interval string) ([]PricePoint, *errors.MyError) {
+	exchange, ok := exchanges[strings.ToLower(name)]
+	if !ok {
+		exchange = exchanges[defaultExchangeName]
+	}
+
+	return exchange.FetchCandles(pair, interval)
+}
+
+// PollHistoricalRange resolves the requested exchange (falling back to GDAX if name is empty or unknown)
+// and fetches pair's candles between start and end at granularity from it, bypassing the named interval
+// enum PollHistorical relies on
+func PollHistoricalRange(name string, pair string, start time.Time, end time.Time, granularity int64) ([]PricePoint, *errors.MyError) {
+	exchange, ok := exchanges[strings.ToLower(name)]
+	if !ok {
+		exchange = exchanges[defaultExchangeName]
+	}
+
+	return exchange.FetchRange(pair, start, end, granularity)
+}
+
+// StreamHistorical resolves the requested exchange (falling back to GDAX if name is empty or unknown)
+// and streams pair's candles for interval to writer as they're fetched, instead of buffering them
+func StreamHistorical(name string, pair string, interval string, writer CandleWriter) *errors.MyError {
+	exchange, ok := exchanges[strings.ToLower(name)]
+	if !ok {
+		exchange = exchanges[defaultExchangeName]
+	}
+
+	return exchange.StreamCandles(pair, interval, writer)
+}
+
+// StreamHistoricalRange resolves the requested exchange (falling back to GDAX if name is empty or
+// unknown) and streams pair's candles between start and end at granularity to writer as they're
+// fetched, bypassing the named interval enum StreamHistorical relies on
+func StreamHistoricalRange(name string, pair string, start time.Time, end time.Time, granularity int64, writer CandleWriter) *errors.MyError {
+	exchange, ok := exchanges[strings.ToLower(name)]
+	if !ok {
+		exchange = exchanges[defaultExchangeName]
+	}
+
+	return exchange.StreamRange(pair, start, end, granularity, writer)
+}
@@ -0,0 +1,70 @@
+package datamodels
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strconv"
+)
+
+// CandleWriter receives PricePoints one at a time as they're fetched, so a handler can stream a large
+// pull straight to its client instead of buffering the full result in memory
+type CandleWriter interface {
+	WritePoint(point PricePoint) error
+}
+
+// CsvCandleWriter streams PricePoints as "timestamp,price\n" rows
+type CsvCandleWriter struct {
+	writer  *csv.Writer
+	flusher http.Flusher
+}
+
+// NewCsvCandleWriter wraps w so PricePoints can be streamed to it as CSV rows. If w is also an
+// http.Flusher, each row is flushed to the client as soon as it's written, the same as NdjsonCandleWriter
+func NewCsvCandleWriter(w io.Writer) *CsvCandleWriter {
+	flusher, _ := w.(http.Flusher)
+	return &CsvCandleWriter{writer: csv.NewWriter(w), flusher: flusher}
+}
+
+func (c *CsvCandleWriter) WritePoint(point PricePoint) error {
+	if err := c.writer.Write([]string{strconv.FormatInt(point.Timestamp, 10), point.Price}); err != nil {
+		return err
+	}
+
+	c.writer.Flush()
+	if err := c.writer.Error(); err != nil {
+		return err
+	}
+
+	if c.flusher != nil {
+		c.flusher.Flush()
+	}
+
+	return nil
+}
+
+// NdjsonCandleWriter streams one PricePoint JSON object per line
+type NdjsonCandleWriter struct {
+	writer  io.Writer
+	flusher http.Flusher
+}
+
+// NewNdjsonCandleWriter wraps w so PricePoints can be streamed to it as newline-delimited JSON. If w is
+// also an http.Flusher, each line is flushed to the client as soon as it's written
+func NewNdjsonCandleWriter(w io.Writer) *NdjsonCandleWriter {
+	flusher, _ := w.(http.Flusher)
+	return &NdjsonCandleWriter{writer: w, flusher: flusher}
+}
+
+func (n *NdjsonCandleWriter) WritePoint(point PricePoint) error {
+	if err := json.NewEncoder(n.writer).Encode(point); err != nil {
+		return err
+	}
+
+	if n.flusher != nil {
+		n.flusher.Flush()
+	}
+
+	return nil
+}
@@ -0,0 +1,242 @@
+package datamodels
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/adamhei/historicalapi/errors"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Supported intervals and their granularities. FTX, like GDAX, keys on candle-width seconds, but only
+// accepts a fixed set of resolutions (15, 60, 300, 900, 3600, 14400, 86400), so 21600 isn't usable here
+var ftxIntervalToGranularity = map[string]int64{
+	TWOYEAR:    dailyBySeconds,
+	YEAR:       dailyBySeconds,
+	SIXMONTH:   dailyBySeconds,
+	THREEMONTH: dailyBySeconds,
+	MONTH:      fourhourBySeconds,
+	WEEK:       hourBySeconds,
+	DAY:        fifteenminuteBySeconds,
+}
+
+const fourhourBySeconds = 14400
+
+const ftxHistoricalEndpointFmt = "https://ftx.com/api/markets/%s/candles"
+
+// ftxCandle mirrors the shape of a single entry in FTX's candles response
+type ftxCandle struct {
+	Time  float64 `json:"time"`
+	Close float64 `json:"close"`
+}
+
+// ftxCandlesResponse mirrors the top-level shape of FTX's candles response
+type ftxCandlesResponse struct {
+	Success bool        `json:"success"`
+	Result  []ftxCandle `json:"result"`
+}
+
+// FtxExchange is the HistoricalExchange implementation backed by FTX's /markets/{market}/candles endpoint
+type FtxExchange struct{}
+
+func (FtxExchange) Name() string {
+	return "ftx"
+}
+
+func (FtxExchange) SupportedGranularities() []string {
+	return []string{
+		strconv.Itoa(dailyBySeconds),
+		strconv.Itoa(fourhourBySeconds),
+		strconv.Itoa(hourBySeconds),
+		strconv.Itoa(fifteenminuteBySeconds),
+	}
+}
+
+// FetchCandles checks interval's validity and attempts to return all FTX candles for pair within that
+// interval, at a pre-determined granularity
+func (f FtxExchange) FetchCandles(pair string, interval string) ([]PricePoint, *errors.MyError) {
+	interval = strings.ToUpper(interval)
+	granularity, ok := ftxIntervalToGranularity[interval]
+	if !ok {
+		return nil, &errors.MyError{Err: fmt.Sprintf("Please provide a valid interval; %s is invalid", interval), ErrorCode: 400}
+	}
+
+	partition := getIntervalPartition(interval)
+
+	return withCache(f.Name(), pair, interval, granularity, partition, func() ([]PricePoint, *errors.MyError) {
+		buckets, myerror := fetchFtxBuckets(pair, granularity, partition)
+		if myerror != nil {
+			return nil, myerror
+		}
+
+		log.Println(fmt.Sprintf("Found %d buckets from FTX", len(buckets)))
+
+		return generalizeFtxBuckets(buckets), nil
+	})
+}
+
+// FetchRange validates a caller-supplied start/end/granularity range and attempts to return all FTX
+// candles for pair within it, auto-partitioning the range into MaxTotalCandles-candle requests
+func (f FtxExchange) FetchRange(pair string, start time.Time, end time.Time, granularity int64) ([]PricePoint, *errors.MyError) {
+	if myerror := ValidateRange(start, end, granularity, f.SupportedGranularities()); myerror != nil {
+		return nil, myerror
+	}
+
+	partition := getCustomPartition(start, end, granularity)
+
+	return withCache(f.Name(), pair, rangeCacheInterval, granularity, partition, func() ([]PricePoint, *errors.MyError) {
+		buckets, myerror := fetchFtxBuckets(pair, granularity, partition)
+		if myerror != nil {
+			return nil, myerror
+		}
+
+		log.Println(fmt.Sprintf("Found %d buckets from FTX", len(buckets)))
+
+		return generalizeFtxBuckets(buckets), nil
+	})
+}
+
+// StreamCandles writes each FTX candle to writer as soon as the chunk of requests covering it returns,
+// rather than waiting for the full interval to resolve before responding. It does not consult the warm
+// cache, since these calls are typically one-off bulk exports rather than repeat lookups
+func (f FtxExchange) StreamCandles(pair string, interval string, writer CandleWriter) *errors.MyError {
+	interval = strings.ToUpper(interval)
+	granularity, ok := ftxIntervalToGranularity[interval]
+	if !ok {
+		return &errors.MyError{Err: fmt.Sprintf("Please provide a valid interval; %s is invalid", interval), ErrorCode: 400}
+	}
+
+	written := make(map[int64]bool)
+	for _, period := range getIntervalPartition(interval) {
+		buckets, myerror := fetchFtxBuckets(pair, granularity, []timePeriod{period})
+		if myerror != nil {
+			return myerror
+		}
+
+		for _, point := range generalizeFtxBuckets(buckets) {
+			if written[point.Timestamp] {
+				continue
+			}
+			written[point.Timestamp] = true
+
+			if err := writer.WritePoint(point); err != nil {
+				return &errors.MyError{Err: err.Error(), ErrorCode: http.StatusInternalServerError}
+			}
+		}
+	}
+
+	return nil
+}
+
+// StreamRange is StreamCandles's raw start/end/granularity counterpart: it validates the range, then
+// writes each FTX candle to writer as soon as the chunk covering it returns, deduping across chunks the
+// same way StreamCandles dedupes across named-interval partitions
+func (f FtxExchange) StreamRange(pair string, start time.Time, end time.Time, granularity int64, writer CandleWriter) *errors.MyError {
+	if myerror := ValidateRange(start, end, granularity, f.SupportedGranularities()); myerror != nil {
+		return myerror
+	}
+
+	written := make(map[int64]bool)
+	for _, period := range getCustomPartition(start, end, granularity) {
+		buckets, myerror := fetchFtxBuckets(pair, granularity, []timePeriod{period})
+		if myerror != nil {
+			return myerror
+		}
+
+		for _, point := range generalizeFtxBuckets(buckets) {
+			if written[point.Timestamp] {
+				continue
+			}
+			written[point.Timestamp] = true
+
+			if err := writer.WritePoint(point); err != nil {
+				return &errors.MyError{Err: err.Error(), ErrorCode: http.StatusInternalServerError}
+			}
+		}
+	}
+
+	return nil
+}
+
+// Given a trading pair, a granularity, and a partition of the range to cover, return the raw candles
+// across that partition
+func fetchFtxBuckets(pair string, granularity int64, intervalPartition []timePeriod) ([]ftxCandle, *errors.MyError) {
+	buckets := make([]ftxCandle, 0)
+	for _, timePeriod := range intervalPartition {
+		requestString, err := buildFtxRequest(pair, granularity, timePeriod.start, timePeriod.end)
+
+		if err != nil {
+			return nil, &errors.MyError{Err: err.Error()}
+		}
+
+		response, err := http.Get(requestString)
+		log.Println(fmt.Sprintf("Querying %s", requestString))
+
+		if err != nil {
+			log.Println("Could not reach ", requestString)
+			return nil, &errors.MyError{Err: "Failed to reach FTX API", ErrorCode: http.StatusInternalServerError}
+		}
+
+		if response.StatusCode == http.StatusOK {
+			ftxResp := new(ftxCandlesResponse)
+			err = json.NewDecoder(response.Body).Decode(ftxResp)
+			response.Body.Close()
+
+			if err != nil {
+				log.Println("Could not decode FTX response")
+				return nil, &errors.MyError{Err: err.Error(), ErrorCode: http.StatusInternalServerError}
+			}
+			if !ftxResp.Success {
+				return nil, &errors.MyError{Err: "FTX API reported an unsuccessful request", ErrorCode: http.StatusInternalServerError}
+			}
+
+			buckets = append(buckets, ftxResp.Result...)
+		} else {
+			response.Body.Close()
+			return nil, &errors.MyError{Err: "FTX API returned a non-200 response", ErrorCode: response.StatusCode}
+		}
+	}
+
+	return buckets, nil
+}
+
+// Convert an array of ftxCandles to the more general PricePoints
+func generalizeFtxBuckets(buckets []ftxCandle) []PricePoint {
+	pricePoints := make([]PricePoint, len(buckets))
+
+	for index, val := range buckets {
+		price := strconv.FormatFloat(val.Close, 'f', -1, 64)
+		pricePoints[index] = PricePoint{Timestamp: int64(val.Time) / 1000, Price: price}
+	}
+
+	return pricePoints
+}
+
+// ftxMarket converts our hyphenated pair convention (e.g. BTC-USD) into FTX's slash-delimited market
+// convention (e.g. BTC/USD)
+func ftxMarket(pair string) string {
+	return strings.ToUpper(strings.Replace(pair, "-", "/", -1))
+}
+
+// Given a trading pair, a granularity, and start and end times, buildFtxRequest returns the formatted
+// GET request URL for the FTX candles API
+// Ex: https://ftx.com/api/markets/BTC/USD/candles?resolution=3600&start_time=...&end_time=...
+func buildFtxRequest(pair string, granularity int64, start time.Time, end time.Time) (string, error) {
+	req, err := http.NewRequest("GET", fmt.Sprintf(ftxHistoricalEndpointFmt, ftxMarket(pair)), nil)
+	if err != nil {
+		log.Println("Could not build FTX historical URL")
+		return "", err
+	}
+
+	q := req.URL.Query()
+
+	q.Add("resolution", strconv.FormatInt(granularity, 10))
+	q.Add("start_time", strconv.FormatInt(start.Unix(), 10))
+	q.Add("end_time", strconv.FormatInt(end.Unix(), 10))
+
+	req.URL.RawQuery = q.Encode()
+	return req.URL.String(), nil
+}
@@ -38,25 +38,133 @@ const (
 	minuteBySeconds        = 60
 )
 
-const gdaxHistoricalEndpoint = "https://api.gdax.com/products/BTC-USD/candles"
+const gdaxHistoricalEndpointFmt = "https://api.gdax.com/products/%s/candles"
 
-// Given an interval, check its validity and attempt to return all GDAX BTC data within that interval, with a
-// pre-determined granularity
-func PollGdaxHistorical(interval string) ([]PricePoint, *errors.MyError) {
+// GdaxExchange is the HistoricalExchange implementation backed by the GDAX historic rates API
+type GdaxExchange struct{}
+
+func (GdaxExchange) Name() string {
+	return "gdax"
+}
+
+func (GdaxExchange) SupportedGranularities() []string {
+	return []string{
+		strconv.Itoa(dailyBySeconds),
+		strconv.Itoa(sixhourBySeconds),
+		strconv.Itoa(hourBySeconds),
+		strconv.Itoa(fifteenminuteBySeconds),
+		strconv.Itoa(fiveminuteBySeconds),
+		strconv.Itoa(minuteBySeconds),
+	}
+}
+
+// FetchCandles checks interval's validity and attempts to return all GDAX candles for pair within that
+// interval, at a pre-determined granularity
+func (g GdaxExchange) FetchCandles(pair string, interval string) ([]PricePoint, *errors.MyError) {
 	interval = strings.ToUpper(interval)
-	if gdaxIntervalToGranularity[string(interval)] == 0 {
+	granularity, ok := gdaxIntervalToGranularity[interval]
+	if !ok {
 		return nil, &errors.MyError{Err: fmt.Sprintf("Please provide a valid interval; %s is invalid", interval), ErrorCode: 400}
 	}
 
-	buckets, myerror := fetchGdaxBuckets(interval)
+	partition := getIntervalPartition(interval)
 
-	if myerror != nil {
+	return withCache(g.Name(), pair, interval, granularity, partition, func() ([]PricePoint, *errors.MyError) {
+		buckets, myerror := fetchGdaxBuckets(pair, granularity, partition)
+		if myerror != nil {
+			return nil, myerror
+		}
+
+		log.Println(fmt.Sprintf("Found %d buckets from GDAX", len(buckets)))
+
+		start, end := partitionBounds(partition)
+		return FillGaps(generalizeGdaxBuckets(buckets), start, end, granularity), nil
+	})
+}
+
+// FetchRange validates a caller-supplied start/end/granularity range and attempts to return all GDAX
+// candles for pair within it, auto-partitioning the range into GDAX's 300-candle-per-request limit
+func (g GdaxExchange) FetchRange(pair string, start time.Time, end time.Time, granularity int64) ([]PricePoint, *errors.MyError) {
+	if myerror := ValidateRange(start, end, granularity, g.SupportedGranularities()); myerror != nil {
 		return nil, myerror
 	}
 
-	log.Println(fmt.Sprintf("Found %d buckets from GDAX", len(buckets)))
+	partition := getCustomPartition(start, end, granularity)
+
+	return withCache(g.Name(), pair, rangeCacheInterval, granularity, partition, func() ([]PricePoint, *errors.MyError) {
+		buckets, myerror := fetchGdaxBuckets(pair, granularity, partition)
+		if myerror != nil {
+			return nil, myerror
+		}
+
+		log.Println(fmt.Sprintf("Found %d buckets from GDAX", len(buckets)))
 
-	return generalizeGdaxBuckets(buckets), nil
+		return FillGaps(generalizeGdaxBuckets(buckets), start.Unix(), end.Unix(), granularity), nil
+	})
+}
+
+// StreamCandles writes each GDAX bucket to writer as soon as the chunk of requests covering it returns,
+// rather than buffering the whole interval first. Unlike FetchCandles it does not run the result through
+// FillGaps - interpolating cleanly needs the full window up front, which would defeat the point of
+// streaming it progressively. It also bypasses the warm cache, since a streamed export is rarely the
+// same request repeated
+func (g GdaxExchange) StreamCandles(pair string, interval string, writer CandleWriter) *errors.MyError {
+	interval = strings.ToUpper(interval)
+	granularity, ok := gdaxIntervalToGranularity[interval]
+	if !ok {
+		return &errors.MyError{Err: fmt.Sprintf("Please provide a valid interval; %s is invalid", interval), ErrorCode: 400}
+	}
+
+	written := make(map[int64]bool)
+	for _, period := range getIntervalPartition(interval) {
+		buckets, myerror := fetchGdaxBuckets(pair, granularity, []timePeriod{period})
+		if myerror != nil {
+			return myerror
+		}
+
+		for _, point := range generalizeGdaxBuckets(buckets) {
+			if written[point.Timestamp] {
+				continue
+			}
+			written[point.Timestamp] = true
+
+			if err := writer.WritePoint(point); err != nil {
+				return &errors.MyError{Err: err.Error(), ErrorCode: http.StatusInternalServerError}
+			}
+		}
+	}
+
+	return nil
+}
+
+// StreamRange is StreamCandles's raw start/end/granularity counterpart: it validates the range, then
+// writes each GDAX bucket to writer as soon as the chunk covering it returns, deduping across chunks
+// the same way StreamCandles dedupes across named-interval partitions
+func (g GdaxExchange) StreamRange(pair string, start time.Time, end time.Time, granularity int64, writer CandleWriter) *errors.MyError {
+	if myerror := ValidateRange(start, end, granularity, g.SupportedGranularities()); myerror != nil {
+		return myerror
+	}
+
+	written := make(map[int64]bool)
+	for _, period := range getCustomPartition(start, end, granularity) {
+		buckets, myerror := fetchGdaxBuckets(pair, granularity, []timePeriod{period})
+		if myerror != nil {
+			return myerror
+		}
+
+		for _, point := range generalizeGdaxBuckets(buckets) {
+			if written[point.Timestamp] {
+				continue
+			}
+			written[point.Timestamp] = true
+
+			if err := writer.WritePoint(point); err != nil {
+				return &errors.MyError{Err: err.Error(), ErrorCode: http.StatusInternalServerError}
+			}
+		}
+	}
+
+	return nil
 }
 
 // Convert an array of GdaxBuckets to the more general PricePoints
@@ -65,23 +173,70 @@ func generalizeGdaxBuckets(buckets [][]float64) []PricePoint {
 
 	for index, val := range buckets {
 		price := strconv.FormatFloat(val[1], 'f', -1, 64)
-		pricePoints[index] = PricePoint{int64(val[0]), price}
+		pricePoints[index] = PricePoint{Timestamp: int64(val[0]), Price: price}
 	}
 
 	return pricePoints
 }
 
-// Given a time interval, return a slice of timestamps and BTC prices from GDAX within that interval looking back from today
+// Candle preserves a GDAX candle in full, rather than collapsing it down to the single price
+// generalizeGdaxBuckets extracts
+type Candle struct {
+	Timestamp int64   `json:"timestamp"`
+	Low       float64 `json:"low"`
+	High      float64 `json:"high"`
+	Open      float64 `json:"open"`
+	Close     float64 `json:"close"`
+	Volume    float64 `json:"volume"`
+}
+
+// Convert an array of GdaxBuckets ([time, low, high, open, close, volume]) to Candles
+func generalizeGdaxCandles(buckets [][]float64) []Candle {
+	candles := make([]Candle, len(buckets))
+
+	for index, val := range buckets {
+		candles[index] = Candle{
+			Timestamp: int64(val[0]),
+			Low:       val[1],
+			High:      val[2],
+			Open:      val[3],
+			Close:     val[4],
+			Volume:    val[5],
+		}
+	}
+
+	return candles
+}
+
+// FetchOHLC checks interval's validity and attempts to return all GDAX candles for pair within that
+// interval, preserving their full OHLCV shape rather than collapsing each to a single price
 //
-// Some time intervals, such as 2 years and 1 year, require multiple requests to GDAX,
-// which is why we treat the intervalPartition as a slice of an arbitrary number of timePeriods/requests to make
-func fetchGdaxBuckets(interval string) ([][]float64, *errors.MyError) {
-	intervalPartition := getIntervalPartition(interval)
-	granularity := gdaxIntervalToGranularity[interval]
+// Binance and FTX don't have an OHLC-preserving path yet, so this lives on GDAX alone rather than on
+// the HistoricalExchange interface
+func FetchOHLC(pair string, interval string) ([]Candle, *errors.MyError) {
+	interval = strings.ToUpper(interval)
+	granularity, ok := gdaxIntervalToGranularity[interval]
+	if !ok {
+		return nil, &errors.MyError{Err: fmt.Sprintf("Please provide a valid interval; %s is invalid", interval), ErrorCode: 400}
+	}
+
+	buckets, myerror := fetchGdaxBuckets(pair, granularity, getIntervalPartition(interval))
+	if myerror != nil {
+		return nil, myerror
+	}
+
+	return generalizeGdaxCandles(buckets), nil
+}
 
+// Given a trading pair, a granularity, and a partition of the range to cover, return a slice of
+// timestamps and prices from GDAX across that partition
+//
+// Some ranges, such as 2 years and 1 year, require multiple requests to GDAX,
+// which is why we treat the intervalPartition as a slice of an arbitrary number of timePeriods/requests to make
+func fetchGdaxBuckets(pair string, granularity int64, intervalPartition []timePeriod) ([][]float64, *errors.MyError) {
 	buckets := make([][]float64, 0)
 	for _, timePeriod := range intervalPartition {
-		requestString, err := buildGdaxRequest(granularity, timePeriod.start, timePeriod.end)
+		requestString, err := buildGdaxRequest(pair, granularity, timePeriod.start, timePeriod.end)
 
 		if err != nil {
 			return nil, &errors.MyError{Err: err.Error()}
@@ -92,7 +247,6 @@ func fetchGdaxBuckets(interval string) ([][]float64, *errors.MyError) {
 
 		if err != nil {
 			log.Println("Could not reach ", requestString)
-			response.Body.Close()
 			return nil, &errors.MyError{Err: "Failed to reach GDAX API", ErrorCode: http.StatusInternalServerError}
 		}
 		if response.StatusCode == http.StatusOK {
@@ -142,10 +296,11 @@ func filterBuckets(start time.Time, end time.Time, buckets [][]float64) [][]floa
 	return filtered
 }
 
-// Given a granularity and start and end times, buildGdaxRequest returns the formatted GET request URL for the GDAX API
+// Given a trading pair, a granularity, and start and end times, buildGdaxRequest returns the formatted GET
+// request URL for the GDAX API
 // Ex: https://api.gdax.com/products/BTC-USD/candles?start=2017-01-15&end=2017-01-16&granularity=3600
-func buildGdaxRequest(granularity int64, start time.Time, end time.Time) (string, error) {
-	req, err := http.NewRequest("GET", gdaxHistoricalEndpoint, nil)
+func buildGdaxRequest(pair string, granularity int64, start time.Time, end time.Time) (string, error) {
+	req, err := http.NewRequest("GET", fmt.Sprintf(gdaxHistoricalEndpointFmt, pair), nil)
 	if err != nil {
 		log.Println("Could not build GDAX historical URL")
 		return "", err
@@ -164,7 +319,7 @@ func buildGdaxRequest(granularity int64, start time.Time, end time.Time) (string
 }
 
 // Given an interval, return a slice partition of that interval into timePeriods in reverse chronological order
-// to preserve order when making consecutive requests to GDAX
+// to preserve order when making consecutive requests to the exchange
 func getIntervalPartition(interval string) []timePeriod {
 	nowRounded := roundTime(time.Now())
 	nowRounded = nowRounded.AddDate(0, 0, 1)
@@ -199,3 +354,39 @@ func getIntervalPartition(interval string) []timePeriod {
 
 	return intervalPartition
 }
+
+// partitionBounds returns the earliest start and latest end across a partition, regardless of the order
+// its timePeriods were built in
+func partitionBounds(partition []timePeriod) (start int64, end int64) {
+	start, end = partition[0].start.Unix(), partition[0].end.Unix()
+
+	for _, period := range partition[1:] {
+		if period.start.Unix() < start {
+			start = period.start.Unix()
+		}
+		if period.end.Unix() > end {
+			end = period.end.Unix()
+		}
+	}
+
+	return start, end
+}
+
+// Given a raw start/end range and a granularity, split it into chunks of at most MaxTotalCandles candles,
+// in chronological order, the same way getIntervalPartition splits a named interval into requests that
+// stay within an exchange's per-request candle limit
+func getCustomPartition(start time.Time, end time.Time, granularity int64) []timePeriod {
+	step := time.Duration(granularity) * time.Second
+	chunkDuration := step * time.Duration(MaxTotalCandles)
+
+	intervalPartition := make([]timePeriod, 0)
+	for chunkStart := start; chunkStart.Before(end); chunkStart = chunkStart.Add(chunkDuration).Add(step) {
+		chunkEnd := chunkStart.Add(chunkDuration)
+		if chunkEnd.After(end) {
+			chunkEnd = end
+		}
+		intervalPartition = append(intervalPartition, timePeriod{chunkStart, chunkEnd})
+	}
+
+	return intervalPartition
+}
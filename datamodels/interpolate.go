@@ -0,0 +1,77 @@
+package datamodels
+
+import "strconv"
+
+// MaxInterpolationGapMultiple bounds how large a gap between two real candles FillGaps will bridge with
+// synthesized points, expressed as a multiple of the granularity. Even a single dropped candle leaves its
+// neighbors 2*granularity apart, so the default has to clear that bar or the common single-candle-drop
+// case (the reason this exists) would never interpolate. A genuine multi-day outage still reads as a
+// hole rather than invented, since its neighbors sit far beyond this multiple
+var MaxInterpolationGapMultiple = 2.5
+
+// FillGaps walks every expected timestamp in [start, end] at granularity and, for each one missing from
+// points, synthesizes a PricePoint by linearly interpolating between its nearest real neighbors - but
+// only when those neighbors are within MaxInterpolationGapMultiple*granularity of each other. Wider gaps
+// are left out entirely, so a genuine outage still reads as a hole rather than a smooth invented line.
+// Synthesized points are marked Interpolated so downstream consumers can tell them apart from real ones
+func FillGaps(points []PricePoint, start int64, end int64, granularity int64) []PricePoint {
+	byTimestamp := make(map[int64]PricePoint, len(points))
+	for _, point := range points {
+		byTimestamp[point.Timestamp] = point
+	}
+
+	maxGap := int64(float64(granularity) * MaxInterpolationGapMultiple)
+
+	filled := make([]PricePoint, 0, len(points))
+	for t := start; t <= end; t += granularity {
+		if point, ok := byTimestamp[t]; ok {
+			filled = append(filled, point)
+			continue
+		}
+
+		before, after, ok := nearestNeighbors(byTimestamp, t, start, end, granularity)
+		if !ok || after.Timestamp-before.Timestamp > maxGap {
+			continue
+		}
+
+		filled = append(filled, interpolatePoint(before, after, t))
+	}
+
+	return filled
+}
+
+// nearestNeighbors scans outward from t for the closest real point on each side, within [start, end]
+func nearestNeighbors(byTimestamp map[int64]PricePoint, t int64, start int64, end int64, granularity int64) (before PricePoint, after PricePoint, ok bool) {
+	foundBefore, foundAfter := false, false
+
+	for candidate := t - granularity; candidate >= start; candidate -= granularity {
+		if point, exists := byTimestamp[candidate]; exists {
+			before, foundBefore = point, true
+			break
+		}
+	}
+
+	for candidate := t + granularity; candidate <= end; candidate += granularity {
+		if point, exists := byTimestamp[candidate]; exists {
+			after, foundAfter = point, true
+			break
+		}
+	}
+
+	return before, after, foundBefore && foundAfter
+}
+
+// interpolatePoint linearly interpolates a price between before and after at timestamp t
+func interpolatePoint(before PricePoint, after PricePoint, t int64) PricePoint {
+	beforePrice, _ := strconv.ParseFloat(before.Price, 64)
+	afterPrice, _ := strconv.ParseFloat(after.Price, 64)
+
+	progress := float64(t-before.Timestamp) / float64(after.Timestamp-before.Timestamp)
+	price := beforePrice + (afterPrice-beforePrice)*progress
+
+	return PricePoint{
+		Timestamp:    t,
+		Price:        strconv.FormatFloat(price, 'f', -1, 64),
+		Interpolated: true,
+	}
+}
@@ -0,0 +1,54 @@
+package datamodels
+
+import "testing"
+
+func TestFillGapsInterpolatesShortGap(t *testing.T) {
+	points := []PricePoint{
+		{Timestamp: 0, Price: "100"},
+		{Timestamp: hourBySeconds * 2, Price: "200"},
+	}
+
+	filled := FillGaps(points, 0, hourBySeconds*2, hourBySeconds)
+	if len(filled) != 3 {
+		t.Fatalf("expected the missing middle candle to be interpolated, got %d points", len(filled))
+	}
+
+	middle := filled[1]
+	if middle.Price != "150" || !middle.Interpolated {
+		t.Errorf("expected an interpolated midpoint price of 150, got %+v", middle)
+	}
+}
+
+func TestFillGapsLeavesWideGapAsHole(t *testing.T) {
+	points := []PricePoint{
+		{Timestamp: 0, Price: "100"},
+		{Timestamp: hourBySeconds * 4, Price: "200"},
+	}
+
+	filled := FillGaps(points, 0, hourBySeconds*4, hourBySeconds)
+	if len(filled) != 2 {
+		t.Fatalf("expected the wide gap to be left as a hole rather than bridged, got %d points", len(filled))
+	}
+	for _, point := range filled {
+		if point.Interpolated {
+			t.Errorf("did not expect any interpolated points, got %+v", point)
+		}
+	}
+}
+
+func TestFillGapsPassesRealPointsThroughUnmarked(t *testing.T) {
+	points := []PricePoint{
+		{Timestamp: 0, Price: "100"},
+		{Timestamp: hourBySeconds, Price: "110"},
+	}
+
+	filled := FillGaps(points, 0, hourBySeconds, hourBySeconds)
+	if len(filled) != 2 {
+		t.Fatalf("expected both real points to pass through, got %d", len(filled))
+	}
+	for _, point := range filled {
+		if point.Interpolated {
+			t.Errorf("real candles should not be marked Interpolated, got %+v", point)
+		}
+	}
+}
@@ -0,0 +1,10 @@
+package datamodels
+
+// PricePoint is the timestamp/price pair every HistoricalExchange implementation resolves its native
+// candle payload down to. Interpolated is set when FillGaps synthesized the point rather than the
+// exchange reporting it
+type PricePoint struct {
+	Timestamp    int64  `json:"timestamp"`
+	Price        string `json:"price"`
+	Interpolated bool   `json:"interpolated,omitempty"`
+}
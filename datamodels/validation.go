@@ -0,0 +1,41 @@
+package datamodels
+
+import (
+	"fmt"
+	"github.com/adamhei/historicalapi/errors"
+	"strconv"
+	"time"
+)
+
+// MaxTotalCandles bounds how many candles a single raw start/end/granularity request may span. It
+// defaults to GDAX's per-request limit of 300, since that's the tightest of the exchanges we poll
+var MaxTotalCandles int64 = 300
+
+// ValidateRange checks a raw start/end/granularity request up front, mirroring the vince request.Validate
+// pattern of rejecting bad input before it reaches the network, rather than letting the exchange reject it
+func ValidateRange(start time.Time, end time.Time, granularity int64, allowedGranularities []string) *errors.MyError {
+	if !end.After(start) {
+		return &errors.MyError{Err: "end must be after start", ErrorCode: 400}
+	}
+
+	if !containsString(allowedGranularities, strconv.FormatInt(granularity, 10)) {
+		return &errors.MyError{Err: fmt.Sprintf("Please provide a valid granularity; %d is invalid", granularity), ErrorCode: 400}
+	}
+
+	totalCandles := int64(end.Sub(start).Seconds()) / granularity
+	if totalCandles > MaxTotalCandles {
+		return &errors.MyError{Err: fmt.Sprintf("Requested range would return %d candles, which exceeds the maximum of %d", totalCandles, MaxTotalCandles), ErrorCode: 400}
+	}
+
+	return nil
+}
+
+func containsString(haystack []string, needle string) bool {
+	for _, item := range haystack {
+		if item == needle {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,45 @@
+package datamodels
+
+import (
+	"testing"
+	"time"
+)
+
+func TestValidateRangeRejectsInvertedRange(t *testing.T) {
+	start := time.Unix(1000, 0)
+	end := time.Unix(500, 0)
+
+	myerror := ValidateRange(start, end, dailyBySeconds, []string{"86400"})
+	if myerror == nil {
+		t.Fatal("expected an error for an end before start")
+	}
+}
+
+func TestValidateRangeRejectsUnlistedGranularity(t *testing.T) {
+	start := time.Unix(0, 0)
+	end := start.Add(time.Hour)
+
+	myerror := ValidateRange(start, end, hourBySeconds, []string{"86400"})
+	if myerror == nil {
+		t.Fatal("expected an error for a granularity outside the allowed set")
+	}
+}
+
+func TestValidateRangeRejectsTooManyCandles(t *testing.T) {
+	start := time.Unix(0, 0)
+	end := start.Add(time.Duration(MaxTotalCandles+1) * time.Second)
+
+	myerror := ValidateRange(start, end, 1, []string{"1"})
+	if myerror == nil {
+		t.Fatal("expected an error for a range exceeding MaxTotalCandles")
+	}
+}
+
+func TestValidateRangeAcceptsValidRange(t *testing.T) {
+	start := time.Unix(0, 0)
+	end := start.Add(time.Hour)
+
+	if myerror := ValidateRange(start, end, hourBySeconds, []string{"3600"}); myerror != nil {
+		t.Fatalf("unexpected error: %v", myerror.Err)
+	}
+}
@@ -0,0 +1,150 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/adamhei/historicalapi/datamodels"
+	"github.com/adamhei/historicalapi/errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// HandleHistorical serves historical candle requests, resolving ?exchange=, ?product= and ?interval=,
+// and negotiating the response body via ?format= or the Accept header between JSON, CSV, and NDJSON.
+// A JSON request may also pass ?aggregate=week&stats=ohlc,band to receive downsampled OHLC/band stats
+// instead of the raw PricePoints. Passing ?start=&end=&granularity= (as Unix seconds) bypasses the
+// named ?interval= enum entirely in favor of a raw, caller-supplied range
+func HandleHistorical(w http.ResponseWriter, r *http.Request) {
+	exchange := r.URL.Query().Get("exchange")
+	product := r.URL.Query().Get("product")
+	interval := r.URL.Query().Get("interval")
+	aggregateWindow := r.URL.Query().Get("aggregate")
+
+	if aggregateWindow != "" {
+		handleAggregate(w, product, interval, aggregateWindow, r.URL.Query().Get("stats"))
+		return
+	}
+
+	if r.URL.Query().Get("start") != "" || r.URL.Query().Get("end") != "" {
+		handleHistoricalRange(w, r, exchange, product)
+		return
+	}
+
+	switch resolveFormat(r) {
+	case "csv":
+		w.Header().Set("Content-Type", "text/csv")
+		if myerror := datamodels.StreamHistorical(exchange, product, interval, datamodels.NewCsvCandleWriter(w)); myerror != nil {
+			writeError(w, myerror)
+		}
+	case "ndjson":
+		w.Header().Set("Content-Type", "application/x-ndjson")
+		if myerror := datamodels.StreamHistorical(exchange, product, interval, datamodels.NewNdjsonCandleWriter(w)); myerror != nil {
+			writeError(w, myerror)
+		}
+	default:
+		points, myerror := datamodels.PollHistorical(exchange, product, interval)
+		if myerror != nil {
+			writeError(w, myerror)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(points)
+	}
+}
+
+// handleHistoricalRange serves the raw ?start=&end=&granularity= mode, parsing all three as Unix
+// seconds and dispatching to the same JSON/CSV/NDJSON negotiation HandleHistorical uses for the
+// named-interval path
+func handleHistoricalRange(w http.ResponseWriter, r *http.Request, exchange string, product string) {
+	start, err := strconv.ParseInt(r.URL.Query().Get("start"), 10, 64)
+	if err != nil {
+		writeError(w, &errors.MyError{Err: "start must be a Unix timestamp in seconds", ErrorCode: http.StatusBadRequest})
+		return
+	}
+
+	end, err := strconv.ParseInt(r.URL.Query().Get("end"), 10, 64)
+	if err != nil {
+		writeError(w, &errors.MyError{Err: "end must be a Unix timestamp in seconds", ErrorCode: http.StatusBadRequest})
+		return
+	}
+
+	granularity, err := strconv.ParseInt(r.URL.Query().Get("granularity"), 10, 64)
+	if err != nil {
+		writeError(w, &errors.MyError{Err: fmt.Sprintf("granularity must be a number of seconds; %q is invalid", r.URL.Query().Get("granularity")), ErrorCode: http.StatusBadRequest})
+		return
+	}
+
+	startTime := time.Unix(start, 0).UTC()
+	endTime := time.Unix(end, 0).UTC()
+
+	switch resolveFormat(r) {
+	case "csv":
+		w.Header().Set("Content-Type", "text/csv")
+		if myerror := datamodels.StreamHistoricalRange(exchange, product, startTime, endTime, granularity, datamodels.NewCsvCandleWriter(w)); myerror != nil {
+			writeError(w, myerror)
+		}
+	case "ndjson":
+		w.Header().Set("Content-Type", "application/x-ndjson")
+		if myerror := datamodels.StreamHistoricalRange(exchange, product, startTime, endTime, granularity, datamodels.NewNdjsonCandleWriter(w)); myerror != nil {
+			writeError(w, myerror)
+		}
+	default:
+		points, myerror := datamodels.PollHistoricalRange(exchange, product, startTime, endTime, granularity)
+		if myerror != nil {
+			writeError(w, myerror)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(points)
+	}
+}
+
+// handleAggregate serves a downsampled window of OHLC/band stats for product. Aggregation is only
+// available against GDAX today, since FetchOHLC is the only path that preserves full candles
+func handleAggregate(w http.ResponseWriter, product string, interval string, aggregateWindow string, statsParam string) {
+	candles, myerror := datamodels.FetchOHLC(product, interval)
+	if myerror != nil {
+		writeError(w, myerror)
+		return
+	}
+
+	stats := strings.Split(statsParam, ",")
+	aggregated, myerror := datamodels.Aggregate(candles, aggregateWindow, stats)
+	if myerror != nil {
+		writeError(w, myerror)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(aggregated)
+}
+
+// resolveFormat prefers an explicit ?format= over the Accept header, falling back to JSON
+func resolveFormat(r *http.Request) string {
+	if format := r.URL.Query().Get("format"); format != "" {
+		return format
+	}
+
+	switch r.Header.Get("Accept") {
+	case "text/csv":
+		return "csv"
+	case "application/x-ndjson":
+		return "ndjson"
+	default:
+		return "json"
+	}
+}
+
+func writeError(w http.ResponseWriter, myerror *errors.MyError) {
+	code := myerror.ErrorCode
+	if code == 0 {
+		code = http.StatusInternalServerError
+	}
+
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(myerror)
+}